@@ -0,0 +1,52 @@
+package unit
+
+import "fmt"
+
+// AddAlias registers alias as an alternate spelling of canonical, so
+// that the two names refer to the same root unit without creating a
+// distinct entry in s (the way a factor-1 AddConversion would).
+// canonical may itself be an alias, in which case alias resolves to
+// whatever canonical ultimately resolves to.
+//
+// If s already has a unit or alias named alias, or canonical is not a
+// known unit or alias, AddAlias returns an error.
+//
+// Example: AddAlias(s, "KB", "kilobyte")
+func AddAlias(s *System, alias, canonical string) error {
+	if _, ok := s.root[alias]; ok {
+		return fmt.Errorf("%v already has a unit named %q", s.name, alias)
+	}
+	if _, ok := s.alias[alias]; ok {
+		return fmt.Errorf("%v already has an alias named %q", s.name, alias)
+	}
+	target := canonicalName(s, canonical)
+	if _, ok := s.root[target]; !ok {
+		return fmt.Errorf("%v has no unit named %q", s.name, canonical)
+	}
+	s.alias[alias] = target
+	return nil
+}
+
+// Canonical reports the canonical unit name that name refers to: name
+// itself if name is a known unit, the target of name if name is a
+// known alias, or ("", false) if name is neither.
+//
+// Example: Canonical(s, "KB") // "kilobyte", true
+func Canonical(s *System, name string) (string, bool) {
+	canon := canonicalName(s, name)
+	if _, ok := s.root[canon]; !ok {
+		return "", false
+	}
+	return canon, true
+}
+
+// canonicalName resolves name through s's alias table, returning name
+// unchanged if it has no alias registered. It does not check whether
+// the result is a known unit; callers that need that should check
+// s.root themselves or call Canonical.
+func canonicalName(s *System, name string) string {
+	if canon, ok := s.alias[name]; ok {
+		return canon
+	}
+	return name
+}
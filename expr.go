@@ -0,0 +1,150 @@
+package unit
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ParseDim parses a human-readable dimensional expression into the
+// numerator and denominator slices expected by AddType.
+//
+// Expressions may use symbolic notation ("m/s", "m*m/s", "kg·m/s^2")
+// or English prose ("meter per second squared"). Supported operators
+// are * and · for multiplication, / for division, and ^ (or a run of
+// Unicode superscript characters, e.g. "s²") for an integer exponent.
+// A negative exponent moves the unit to the other side of the
+// fraction, so "s^-1" in the numerator is equivalent to "s" in the
+// denominator.
+//
+// As with AddType, ParseDim rejects any expression that simplifies,
+// i.e. one where the same root unit appears in both the numerator
+// and the denominator.
+//
+// Example: ParseDim(s, "m/s") // []string{"m"}, []string{"s"}, nil
+func ParseDim(s *System, expr string) (num, den []string, err error) {
+	expr = normalizeExpr(expr)
+	if expr == "" {
+		return nil, nil, fmt.Errorf("%v: empty unit expression", s.name)
+	}
+
+	chains := strings.Split(expr, "/")
+	numChain := chains[0]
+	denChain := strings.Join(chains[1:], "*")
+
+	if err := parseChain(s, numChain, &num, &den); err != nil {
+		return nil, nil, err
+	}
+	if err := parseChain(s, denChain, &den, &num); err != nil {
+		return nil, nil, err
+	}
+
+	for _, n := range num {
+		for _, d := range den {
+			if s.root[canonicalName(s, n)].name == s.root[canonicalName(s, d)].name {
+				return nil, nil, fmt.Errorf("%v: expression %q can be simplified: %q appears in both numerator and denominator", s.name, expr, n)
+			}
+		}
+	}
+	return num, den, nil
+}
+
+// parseChain parses the * separated terms of chain (a numerator or
+// denominator) and appends them to *into. A term with a negative
+// exponent is appended to *against (the opposite side) instead.
+func parseChain(s *System, chain string, into, against *[]string) error {
+	if chain == "" {
+		return nil
+	}
+	for _, term := range strings.Split(chain, "*") {
+		if term == "" {
+			return fmt.Errorf("%v: invalid unit expression near %q", s.name, chain)
+		}
+		name, exp, err := parseTerm(s, term)
+		if err != nil {
+			return err
+		}
+		dst := into
+		if exp < 0 {
+			dst = against
+			exp = -exp
+		}
+		for i := 0; i < exp; i++ {
+			*dst = append(*dst, name)
+		}
+	}
+	return nil
+}
+
+// parseTerm splits a single term ("m", "s^2", "s^-1") into its unit
+// name and integer exponent, and confirms the unit is registered in s.
+func parseTerm(s *System, term string) (name string, exp int, err error) {
+	name = term
+	exp = 1
+	if i := strings.IndexByte(term, '^'); i >= 0 {
+		name = term[:i]
+		exp, err = strconv.Atoi(term[i+1:])
+		if err != nil {
+			return "", 0, fmt.Errorf("%v: invalid exponent in %q: %v", s.name, term, err)
+		}
+		if exp == 0 {
+			return "", 0, fmt.Errorf("%v: invalid exponent 0 in %q", s.name, term)
+		}
+	}
+	if name == "" {
+		return "", 0, fmt.Errorf("%v: invalid unit expression near %q", s.name, term)
+	}
+	if _, ok := s.root[canonicalName(s, name)]; !ok {
+		return "", 0, fmt.Errorf("%v has no unit named %q", s.name, name)
+	}
+	return name, exp, nil
+}
+
+var (
+	reSuperscript = regexp.MustCompile(`[⁰¹²³⁴⁵⁶⁷⁸⁹⁻]+`)
+	reSquared     = regexp.MustCompile(`(?i)(\S+)\s+squared\b`)
+	reCubed       = regexp.MustCompile(`(?i)(\S+)\s+cubed\b`)
+	rePer         = regexp.MustCompile(`(?i)\s+per\s+`)
+	reOpSpace     = regexp.MustCompile(`\s*([*/^])\s*`)
+	reWhitespace  = regexp.MustCompile(`\s+`)
+)
+
+var superscriptDigits = map[rune]byte{
+	'⁰': '0', '¹': '1', '²': '2', '³': '3', '⁴': '4',
+	'⁵': '5', '⁶': '6', '⁷': '7', '⁸': '8', '⁹': '9',
+	'⁻': '-',
+}
+
+// normalizeExpr rewrites prose and Unicode superscripts into the
+// symbolic form ("m/s", "s^2") that the rest of ParseDim understands.
+func normalizeExpr(expr string) string {
+	expr = reSuperscript.ReplaceAllStringFunc(expr, func(run string) string {
+		var sb strings.Builder
+		sb.WriteByte('^')
+		for _, r := range run {
+			sb.WriteByte(superscriptDigits[r])
+		}
+		return sb.String()
+	})
+	expr = reSquared.ReplaceAllString(expr, "$1^2")
+	expr = reCubed.ReplaceAllString(expr, "$1^3")
+	expr = rePer.ReplaceAllString(expr, "/")
+	expr = strings.ReplaceAll(expr, "·", "*")
+	expr = reOpSpace.ReplaceAllString(expr, "$1")
+	expr = reWhitespace.ReplaceAllString(strings.TrimSpace(expr), "*")
+	return expr
+}
+
+// AddTypeExpr is a convenience wrapper around ParseDim and AddType,
+// for callers who would rather write a unit expression than assemble
+// num and den slices by hand.
+//
+// Example: type metersPerSecond float64; AddTypeExpr[metersPerSecond](s, "m/s")
+func AddTypeExpr[T ~float64](s *System, expr string) error {
+	num, den, err := ParseDim(s, expr)
+	if err != nil {
+		return err
+	}
+	return AddType[T](s, num, den)
+}
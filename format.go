@@ -0,0 +1,192 @@
+package unit
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// Format rescales v into whichever registered unit of its dimensional
+// type puts the magnitude in a human-friendly range (1 <= |x| < 1000
+// when possible), and returns the rescaled value along with the name
+// of the unit it was rescaled into. For a derived type (e.g. m/s),
+// candidate units are built from the cross product of the conversions
+// registered for each of its component roots; ties are broken toward
+// the combination that minimizes the spread between the component
+// units' scales.
+//
+// Example: Format(s, meter(5000)) // 5, "km", nil
+func Format[T ~float64](s *System, v T) (value float64, unitName string, err error) {
+	rt := reflect.TypeOf(v)
+	d, ok := s.typOf[rt]
+	if !ok {
+		return 0, "", fmt.Errorf("%v has no unit associated with type %v", s.name, rt)
+	}
+	raw := float64(v) * ratToFloat(d.factor)
+	return formatRaw(s, raw, d)
+}
+
+// FormatString is like Format, but returns a single ready-to-print
+// string, e.g. "5 km".
+func FormatString[T ~float64](s *System, v T) (string, error) {
+	val, name, err := Format[T](s, v)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%v %s", val, name), nil
+}
+
+// formatRaw picks the nicest registered unit for a base-scale value
+// raw (i.e. a value already expressed in factor-1 root units) with
+// dimension d, and returns it rescaled into that unit.
+func formatRaw(s *System, raw float64, d dim) (float64, string, error) {
+	candidates, err := formatCandidates(s, d)
+	if err != nil {
+		return 0, "", err
+	}
+	best := bestCandidate(candidates, func(c formatCandidate) float64 {
+		return niceness(raw / c.factor)
+	})
+	return raw / best.factor, best.name, nil
+}
+
+// bestCandidate picks the candidate that minimizes score, breaking
+// ties first by the narrowest logRange, then by the shortest (then
+// alphabetically first) name - so that e.g. an alias like "km" wins
+// over its equally-scaled long form "kilometer".
+func bestCandidate(candidates []formatCandidate, score func(formatCandidate) float64) formatCandidate {
+	var best formatCandidate
+	var bestScore, bestRange float64
+	for i, c := range candidates {
+		sc := score(c)
+		if i == 0 || sc < bestScore ||
+			(sc == bestScore && c.logRange < bestRange) ||
+			(sc == bestScore && c.logRange == bestRange && betterName(c.name, best.name)) {
+			best, bestScore, bestRange = c, sc, c.logRange
+		}
+	}
+	return best
+}
+
+// betterName reports whether a should be preferred over b as a
+// display name: shorter first, then alphabetically.
+func betterName(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// niceness scores how far a scaled value is from the "nice" display
+// range [1, 1000); 0 means it's already in range.
+func niceness(v float64) float64 {
+	av := math.Abs(v)
+	if av == 0 {
+		return 0
+	}
+	if av >= 1 && av < 1000 {
+		return 0
+	}
+	if av < 1 {
+		return math.Log10(1 / av)
+	}
+	return math.Log10(av / 999)
+}
+
+type formatCandidate struct {
+	name     string
+	factor   float64
+	logRange float64
+}
+
+// formatCandidates returns every way to name d using the units and
+// aliases registered in s: the cross product of the registered
+// conversions (and their aliases) for each root dimension d.vec uses.
+func formatCandidates(s *System, d dim) ([]formatCandidate, error) {
+	var idxs []int
+	for idx, exp := range d.vec {
+		if exp != 0 {
+			idxs = append(idxs, idx)
+		}
+	}
+	if len(idxs) == 0 {
+		return nil, fmt.Errorf("%v: dimensionless type has no unit to format into", s.name)
+	}
+
+	type namedBasic struct {
+		name   string
+		factor *big.Rat
+	}
+	byIdx := make(map[int][]namedBasic)
+	for name, b := range s.root {
+		byIdx[b.idx] = append(byIdx[b.idx], namedBasic{name: name, factor: b.factor})
+	}
+	for alias, canon := range s.alias {
+		b := s.root[canon]
+		byIdx[b.idx] = append(byIdx[b.idx], namedBasic{name: alias, factor: b.factor})
+	}
+	for _, idx := range idxs {
+		if len(byIdx[idx]) == 0 {
+			return nil, fmt.Errorf("%v: no units registered for one of %v's root dimensions", s.name, d)
+		}
+	}
+
+	var combos []formatCandidate
+	var build func(i int, num, den []string, factor float64, lo, hi float64)
+	build = func(i int, num, den []string, factor float64, lo, hi float64) {
+		if i == len(idxs) {
+			name := strings.Join(num, "*")
+			if len(den) > 0 {
+				if name == "" {
+					name = "1"
+				}
+				name += "/" + strings.Join(den, "*")
+			}
+			combos = append(combos, formatCandidate{name: name, factor: factor, logRange: hi - lo})
+			return
+		}
+		idx := idxs[i]
+		exp := d.vec[idx]
+		for _, b := range byIdx[idx] {
+			f := ratToFloat(b.factor)
+			logF := math.Log10(f)
+			nlo, nhi := lo, hi
+			if logF < nlo {
+				nlo = logF
+			}
+			if logF > nhi {
+				nhi = logF
+			}
+			var nnum, nden []string
+			n := exp
+			if n < 0 {
+				n = -n
+			}
+			if exp > 0 {
+				nnum = append(append([]string{}, num...), repeat(b.name, n)...)
+				nden = den
+			} else {
+				nnum = num
+				nden = append(append([]string{}, den...), repeat(b.name, n)...)
+			}
+			build(i+1, nnum, nden, factor*math.Pow(f, float64(exp)), nlo, nhi)
+		}
+	}
+	build(0, nil, nil, 1, math.Inf(1), math.Inf(-1))
+	return combos, nil
+}
+
+func repeat(name string, n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		out[i] = name
+	}
+	return out
+}
+
+func ratToFloat(r *big.Rat) float64 {
+	f, _ := r.Float64()
+	return f
+}
@@ -0,0 +1,77 @@
+package unit
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Rescale rescales a slice of dimensionally-compatible values - not
+// necessarily all of the same Go type, the way args to Combine
+// aren't - into whichever of their units has the smallest factor,
+// e.g. a mix of meter, kilometer, and gigameter values all end up in
+// meters, even if a smaller unit such as millimeter is registered. It returns the rescaled values
+// in the same order as values, along with the name of the unit they
+// were rescaled into.
+//
+// This is the batch analogue of Convert: instead of N calls each
+// choosing its own target unit, Rescale picks one target for the
+// whole slice, suitable for summing, plotting, or CSV export.
+func Rescale(s *System, values []any) ([]float64, string, error) {
+	if len(values) == 0 {
+		return nil, "", fmt.Errorf("%v: Rescale needs at least one value", s.name)
+	}
+
+	raws := make([]float64, len(values))
+	var family dim
+	var minFactor float64
+	for i, v := range values {
+		rt := reflect.TypeOf(v)
+		d, ok := s.typOf[rt]
+		if !ok {
+			return nil, "", fmt.Errorf("%v has no unit associated with type %v", s.name, rt)
+		}
+		if i == 0 {
+			family = d
+		} else if !convertible(family, d) {
+			return nil, "", fmt.Errorf("%v cannot rescale %v together with %v", s.name, rt, reflect.TypeOf(values[0]))
+		}
+		f := ratToFloat(d.factor)
+		if i == 0 || f < minFactor {
+			minFactor = f
+		}
+		raws[i] = reflect.ValueOf(v).Float() * f
+	}
+
+	candidates, err := formatCandidates(s, family)
+	if err != nil {
+		return nil, "", err
+	}
+	best := bestCandidate(candidates, func(c formatCandidate) float64 {
+		return math.Abs(math.Log10(c.factor) - math.Log10(minFactor))
+	})
+
+	out := make([]float64, len(raws))
+	for i, raw := range raws {
+		out[i] = raw / best.factor
+	}
+	return out, best.name, nil
+}
+
+// CommonUnit is like Rescale, but sums the rescaled values into a
+// single total instead of returning them individually. It's the
+// convenience form for when all you want is a combined measurement
+// in a sensible common unit, e.g. the total distance recorded by a
+// mix of meter- and kilometer-valued readings.
+func CommonUnit[T ~float64](s *System, values ...any) (T, string, error) {
+	var zero T
+	rescaled, name, err := Rescale(s, values)
+	if err != nil {
+		return zero, "", err
+	}
+	var sum float64
+	for _, v := range rescaled {
+		sum += v
+	}
+	return T(sum), name, nil
+}
@@ -0,0 +1,66 @@
+package unit
+
+// SIPrefix describes a multiplicative unit prefix, in both its short
+// symbolic form (e.g. "k") and its long word form (e.g. "kilo").
+type SIPrefix struct {
+	Short    string
+	Long     string
+	AltShort string // alternate short spelling, e.g. "u" for micro's "µ"
+	Factor   float64
+}
+
+var (
+	siPico  = SIPrefix{Short: "p", Long: "pico", Factor: 1e-12}
+	siNano  = SIPrefix{Short: "n", Long: "nano", Factor: 1e-9}
+	siMicro = SIPrefix{Short: "µ", Long: "micro", AltShort: "u", Factor: 1e-6}
+	siMilli = SIPrefix{Short: "m", Long: "milli", Factor: 1e-3}
+	siKilo  = SIPrefix{Short: "k", Long: "kilo", Factor: 1e3}
+	siMega  = SIPrefix{Short: "M", Long: "mega", Factor: 1e6}
+	siGiga  = SIPrefix{Short: "G", Long: "giga", Factor: 1e9}
+	siTera  = SIPrefix{Short: "T", Long: "tera", Factor: 1e12}
+
+	siKibi = SIPrefix{Short: "Ki", Long: "kibi", Factor: 1024}
+	siMebi = SIPrefix{Short: "Mi", Long: "mebi", Factor: 1024 * 1024}
+	siGibi = SIPrefix{Short: "Gi", Long: "gibi", Factor: 1024 * 1024 * 1024}
+)
+
+// SIMetric is the common set of decimal SI prefixes, pico through tera.
+var SIMetric = []SIPrefix{siPico, siNano, siMicro, siMilli, siKilo, siMega, siGiga, siTera}
+
+// SIBinary is the set of binary (power-of-1024) prefixes, for
+// byte-like units: kibi, mebi, gibi.
+var SIBinary = []SIPrefix{siKibi, siMebi, siGibi}
+
+// SIAll is SIMetric and SIBinary combined.
+var SIAll = append(append([]SIPrefix{}, SIMetric...), SIBinary...)
+
+// AddSIPrefixes registers, for each of prefixes, a conversion from
+// base to base prefixed by it, plus short-form and (for micro)
+// alternate-ASCII-form aliases to that conversion.
+//
+// The long form (prefix.Long + base) is the unit actually registered
+// via AddConversion; the short form (prefix.Short + base), and
+// prefix.AltShort + base when set, are added as aliases to it via
+// AddAlias rather than as distinct conversions, per the alias design.
+//
+// Example: AddBasic(s, "m"); AddSIPrefixes(s, "m", SIMetric...)
+// registers "kilom" (1000 m) with "km" as an alias, "millim"
+// (0.001 m) with "mm" as an alias, "micro"+"m" with both "µm" and
+// "um" as aliases, and so on, for every prefix in SIMetric.
+func AddSIPrefixes(s *System, base string, prefixes ...SIPrefix) error {
+	for _, p := range prefixes {
+		long := p.Long + base
+		if err := AddConversion(s, base, long, p.Factor); err != nil {
+			return err
+		}
+		if err := AddAlias(s, p.Short+base, long); err != nil {
+			return err
+		}
+		if p.AltShort != "" {
+			if err := AddAlias(s, p.AltShort+base, long); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ package unit
 
 import (
 	"fmt"
+	"math"
 	"math/big"
 	"reflect"
 	"sort"
@@ -20,6 +21,57 @@ type System struct {
 	name  string               // optional, printed in errors
 	typOf map[reflect.Type]dim // Go type => associated unit
 	root  map[string]basic     // unit name => root unit
+	alias map[string]string    // alias name => canonical unit name
+}
+
+// Merge combines multiple unit systems into one.
+// Name or type conflicts result in errors.
+// This lets you define orthogonal units systems (mass, time, etc.) separately
+// and then combine just the ones that you need.
+func Merge(systems ...*System) (*System, error) {
+	buf := new(strings.Builder)
+	typOf := make(map[reflect.Type]dim)
+	root := make(map[string]basic)
+	alias := make(map[string]string)
+	tot := 0
+	for _, s := range systems {
+		tot += len(s.root)
+	}
+	idx := 0
+	for i, s := range systems {
+		if i > 0 {
+			buf.WriteString("+")
+		}
+		buf.WriteString(s.name)
+		for k, v := range s.typOf {
+			if _, ok := typOf[k]; ok {
+				return nil, fmt.Errorf("duplicate registered type %v", k)
+			}
+			vec := make([]int, tot)
+			copy(vec[idx:], v.vec)
+			typOf[k] = dim{num: v.num, den: v.den, factor: v.factor, offset: v.offset, vec: vec}
+		}
+		for k, v := range s.root {
+			if _, ok := root[k]; ok {
+				return nil, fmt.Errorf("duplicate registered unit %q", k)
+			}
+			if _, ok := alias[k]; ok {
+				return nil, fmt.Errorf("unit %q conflicts with a registered alias", k)
+			}
+			root[k] = basic{name: v.name, idx: idx + v.idx, factor: v.factor, offset: v.offset}
+		}
+		for k, v := range s.alias {
+			if _, ok := alias[k]; ok {
+				return nil, fmt.Errorf("duplicate registered alias %q", k)
+			}
+			if _, ok := root[k]; ok {
+				return nil, fmt.Errorf("alias %q conflicts with a registered unit", k)
+			}
+			alias[k] = v
+		}
+		idx += len(s.root)
+	}
+	return &System{name: buf.String(), typOf: typOf, root: root, alias: alias}, nil
 }
 
 // NewSystem creates a new units system named name.
@@ -29,13 +81,19 @@ type System struct {
 // Add all basic units and conversions, then add all types, then use.
 // Once all additions are completed, a unit system is concurrency-safe.
 func NewSystem(name string) *System {
-	return &System{name: name, typOf: make(map[reflect.Type]dim), root: make(map[string]basic)}
+	return &System{
+		name:  name,
+		typOf: make(map[reflect.Type]dim),
+		root:  make(map[string]basic),
+		alias: make(map[string]string),
+	}
 }
 
 type basic struct {
 	name   string
 	idx    int
 	factor *big.Rat
+	offset *big.Rat // root = basic*factor + offset; zero for non-affine units
 }
 
 // dim is a dimensional unit.
@@ -43,6 +101,7 @@ type dim struct {
 	num    []string
 	den    []string
 	factor *big.Rat
+	offset *big.Rat // nonzero only for a type that is exactly one affine unit
 	vec    []int
 }
 
@@ -69,7 +128,10 @@ func AddBasic(s *System, name string) error {
 	if _, ok := s.root[name]; ok {
 		return fmt.Errorf("%v already has a unit named %q", s.name, name)
 	}
-	s.root[name] = basic{name: name, factor: big.NewRat(1, 1), idx: len(s.root)}
+	if _, ok := s.alias[name]; ok {
+		return fmt.Errorf("%v already has an alias named %q", s.name, name)
+	}
+	s.root[name] = basic{name: name, factor: big.NewRat(1, 1), offset: big.NewRat(0, 1), idx: len(s.root)}
 	return nil
 }
 
@@ -77,14 +139,62 @@ func AddBasic(s *System, name string) error {
 // If s has no unit named from, or already has a unit named to, AddConversion returns an error.
 // Example: AddConversion(s, "meter", "kilometer", 1000)
 func AddConversion(s *System, from, to string, factor float64) error {
-	f, ok := s.root[from]
+	f, ok := s.root[canonicalName(s, from)]
 	if !ok {
 		return fmt.Errorf("%v has no unit named %q", s.name, from)
 	}
 	if _, ok := s.root[to]; ok {
 		return fmt.Errorf("%v already has a unit named %q", s.name, to)
 	}
-	s.root[to] = basic{name: from, factor: newRat(factor), idx: f.idx}
+	if _, ok := s.alias[to]; ok {
+		return fmt.Errorf("%v already has an alias named %q", s.name, to)
+	}
+	// f.name and f.factor already resolve all the way to a root unit,
+	// so chaining through them handles conversions built on top of
+	// other conversions (e.g. gigameter defined in terms of kilometer).
+	rf := newRat(factor)
+	rf = rf.Mul(rf, f.factor)
+	s.root[to] = basic{name: f.name, factor: rf, offset: new(big.Rat).Set(f.offset), idx: f.idx}
+	return nil
+}
+
+// AddAffineConversion adds a conversion that also carries an additive
+// offset: to = from*factor + offset, where from and to are values
+// measured in those units. Unlike AddConversion, whose factor is how
+// many from units make one to unit, factor here maps a from value to a
+// to value, so AddAffineConversion(s, "meter", "kilometer", 0.001, 0)
+// registers the same unit as AddConversion(s, "meter", "kilometer", 1000).
+//
+// The offset is only ever applied when converting a pure, single-root
+// type (one with exponent 1 and nothing else in its num or den, e.g.
+// a plain "celsius" type). Affine units are not closed under
+// multiplication, so AddType refuses to build a derived type (one
+// with a den, a repeated root, or more than one root) out of an
+// affine unit, and Combine refuses to operate on one.
+//
+// Example: AddAffineConversion(s, "kelvin", "celsius", 1, -273.15)
+func AddAffineConversion(s *System, from, to string, factor, offset float64) error {
+	f, ok := s.root[canonicalName(s, from)]
+	if !ok {
+		return fmt.Errorf("%v has no unit named %q", s.name, from)
+	}
+	if _, ok := s.root[to]; ok {
+		return fmt.Errorf("%v already has a unit named %q", s.name, to)
+	}
+	if _, ok := s.alias[to]; ok {
+		return fmt.Errorf("%v already has an alias named %q", s.name, to)
+	}
+	if factor == 0 || math.IsNaN(factor) || math.IsInf(factor, 0) {
+		return fmt.Errorf("%v: invalid factor %v", s.name, factor)
+	}
+	// to = from*factor + offset, and root = from*f.factor + f.offset, so
+	// root = to*(f.factor/factor) + (f.offset - offset*f.factor/factor).
+	// As in AddConversion, chaining through f.name/f.factor/f.offset
+	// (which already resolve to a root unit) handles conversions
+	// built on top of other conversions.
+	newFactor := new(big.Rat).Quo(f.factor, newRat(factor))
+	newOffset := new(big.Rat).Sub(f.offset, new(big.Rat).Mul(newRat(offset), newFactor))
+	s.root[to] = basic{name: f.name, factor: newFactor, offset: newOffset, idx: f.idx}
 	return nil
 }
 
@@ -106,8 +216,9 @@ func AddType[T ~float64](s *System, num, den []string) error {
 	vec := make([]int, len(s.root))
 	isNum := make(map[string]string)
 	var canonNum []string
+	var roots []basic
 	for _, n := range num {
-		root, ok := s.root[n]
+		root, ok := s.root[canonicalName(s, n)]
 		if !ok {
 			return fmt.Errorf("%v has no unit named %q", s.name, n)
 		}
@@ -115,10 +226,11 @@ func AddType[T ~float64](s *System, num, den []string) error {
 		factor = factor.Mul(factor, root.factor)
 		isNum[root.name] = n
 		vec[root.idx]++
+		roots = append(roots, root)
 	}
 	var canonDen []string
 	for _, d := range den {
-		root, ok := s.root[d]
+		root, ok := s.root[canonicalName(s, d)]
 		if !ok {
 			return fmt.Errorf("%v has no unit named %q", s.name, d)
 		}
@@ -128,10 +240,28 @@ func AddType[T ~float64](s *System, num, den []string) error {
 		canonDen = append(canonDen, root.name)
 		factor = factor.Quo(factor, root.factor)
 		vec[root.idx]--
+		roots = append(roots, root)
 	}
 	sort.Strings(canonNum)
 	sort.Strings(canonDen)
-	s.typOf[rt] = dim{num: canonNum, den: canonDen, factor: factor, vec: vec}
+
+	// Affine units (those with a nonzero offset) are only meaningful
+	// on their own: offsets don't compose under multiplication or
+	// repetition, so reject any derived type that uses an affine root
+	// at all.
+	offset := newRat(0)
+	if len(num) == 1 && len(den) == 0 {
+		offset = roots[0].offset
+	} else {
+		names := append(append([]string{}, num...), den...)
+		for i, root := range roots {
+			if root.offset.Sign() != 0 {
+				return fmt.Errorf("%v: affine unit %q cannot be used in a derived type; affine units are not closed under multiplication", s.name, names[i])
+			}
+		}
+	}
+
+	s.typOf[rt] = dim{num: canonNum, den: canonDen, factor: factor, offset: offset, vec: vec}
 	return nil
 }
 
@@ -152,9 +282,12 @@ func Convert[To ~float64](s *System, from any) (To, error) {
 	if !convertible(toDim, fromDim) {
 		return to, fmt.Errorf("%s cannot convert from %v to %v", s.name, fromTyp, toTyp)
 	}
+	// result = (from*fromDim.factor + fromDim.offset - toDim.offset) / toDim.factor
 	result := newRatAny(from)
-	result = result.Quo(result, toDim.factor)
 	result = result.Mul(result, fromDim.factor)
+	result = result.Add(result, fromDim.offset)
+	result = result.Sub(result, toDim.offset)
+	result = result.Quo(result, toDim.factor)
 	f, _ := result.Float64()
 	return To(f), nil
 }
@@ -170,6 +303,9 @@ func Combine[To ~float64](s *System, args ...any) (To, error) {
 	if !ok {
 		return to, fmt.Errorf("%s has no unit associated with type %v", s.name, toTyp)
 	}
+	if toDim.offset.Sign() != 0 {
+		return to, fmt.Errorf("%s: affine type %v is not closed under multiplication, cannot Combine into it", s.name, toTyp)
+	}
 
 	veclen := len(s.root)
 	var vecs [][]int
@@ -183,87 +319,106 @@ func Combine[To ~float64](s *System, args ...any) (To, error) {
 		if len(argDim.vec) != veclen {
 			return to, fmt.Errorf("%s was constructed out of order, please see unit.NewSystem docs", s.name)
 		}
+		if argDim.offset.Sign() != 0 {
+			return to, fmt.Errorf("%s: affine type %v is not closed under multiplication, cannot Combine with it", s.name, argTyp)
+		}
 		vecs = append(vecs, argDim.vec)
 		factors = append(factors, argDim.factor)
 	}
-	if len(vecs) > 16 {
-		return to, fmt.Errorf("too many arguments to Combine, max is 16, got %d", len(vecs))
-	}
-	bits, found, ambiguous := solve(vecs, toDim.vec)
+	exps, found, ambiguous := solve(vecs, toDim.vec)
 	if ambiguous {
 		return to, fmt.Errorf("ambiguous conversion") // TODO: better error
 	}
 	if !found {
 		return to, fmt.Errorf("impossible conversion") // TODO: better error
 	}
-	result := newRat(1)
+	result := 1.0
 	for i := range vecs {
-		factor := factors[i]
-		val := newRatAny(args[i])
-		if bits.at(i) == -1 {
-			// Divide
-			result = result.Quo(result, val)
-			result = result.Quo(result, factor)
-		} else {
-			// Multiply
-			result = result.Mul(result, val)
-			result = result.Mul(result, factor)
+		exp, _ := exps[i].Float64()
+		factor := ratToFloat(factors[i])
+		val := reflect.ValueOf(args[i]).Float()
+		if val < 0 && !exps[i].IsInt() {
+			return to, fmt.Errorf("%s: cannot raise negative value %v to non-integer exponent %v", s.name, val, exps[i])
 		}
+		result *= math.Pow(val*factor, exp)
 	}
-	result = result.Quo(result, toDim.factor)
-	f, _ := result.Float64()
-	return To(f), nil
+	result /= ratToFloat(toDim.factor)
+	return To(result), nil
 }
 
-// solve finds exactly one combination of inputs that generates out.
-// If it doesn't find any, target is nil.
-// If it finds more than one, unambiguous is false.
+// solve finds the unique combination of exponents that generates out
+// from in, by row-reducing the system of linear equations
+//
+//	for all 0 <= x < X, out[x] = sum over 0 <= n < N of in[n][x] * target[n]
 //
-// Assume in has length N and inner length X.
-// Out must have length X as well.
-// The targets we are seeking are slices of length N such that:
-// (a) every element of target is -1 or 1 and
-// (b) for all 0 <= x < X, output[x] = sum over 0 <= n < N of input[n][x] * target[n].
-func solve(in [][]int, out []int) (target bitset32, found, ambiguous bool) {
+// over big.Rat, where in has length N and inner length X, and out
+// has length X. If the system is inconsistent, found is false. If it
+// is underdetermined (some target is free to vary), ambiguous is
+// true. Otherwise target holds the unique rational solution.
+func solve(in [][]int, out []int) (target []*big.Rat, found, ambiguous bool) {
 	n := len(in)
-	if n > 16 {
-		panic("solve: too big")
-	}
 	x := len(out)
-	sum := make([]int, x)
-	// Do this the stupid, exponential way.
-	// There's probably a better way. I don't know it.
-NextBits:
-	for bits := bitset32(0); bits < 1<<n; bits++ {
-		copy(sum, out)
-		for i, vec := range in {
-			mul := bits.at(i)
-			for x, v := range vec {
-				sum[x] += -1 * v * mul
-			}
+
+	// rows is the augmented matrix [A | out], where column c < n of
+	// row r is in[c][r], and the last column is out[r].
+	rows := make([][]*big.Rat, x)
+	for r := 0; r < x; r++ {
+		row := make([]*big.Rat, n+1)
+		for c := 0; c < n; c++ {
+			row[c] = big.NewRat(int64(in[c][r]), 1)
 		}
-		for _, s := range sum {
-			if s != 0 {
-				continue NextBits
+		row[n] = big.NewRat(int64(out[r]), 1)
+		rows[r] = row
+	}
+
+	// Row-reduce to reduced row echelon form, recording which column
+	// each successive row pivots on.
+	var pivotCols []int
+	pivotRow := 0
+	for col := 0; col < n && pivotRow < x; col++ {
+		sel := -1
+		for r := pivotRow; r < x; r++ {
+			if rows[r][col].Sign() != 0 {
+				sel = r
+				break
 			}
 		}
-		if found {
-			return 0, true, true
+		if sel == -1 {
+			continue // col has no pivot: it's a free variable
 		}
-		target = bits
-		found = true
-	}
-	if found {
-		return target, true, false
+		rows[pivotRow], rows[sel] = rows[sel], rows[pivotRow]
+		pivot := new(big.Rat).Set(rows[pivotRow][col])
+		for c := col; c <= n; c++ {
+			rows[pivotRow][c].Quo(rows[pivotRow][c], pivot)
+		}
+		for r := 0; r < x; r++ {
+			if r == pivotRow || rows[r][col].Sign() == 0 {
+				continue
+			}
+			factor := new(big.Rat).Set(rows[r][col])
+			for c := col; c <= n; c++ {
+				rows[r][c].Sub(rows[r][c], new(big.Rat).Mul(factor, rows[pivotRow][c]))
+			}
+		}
+		pivotCols = append(pivotCols, col)
+		pivotRow++
 	}
-	return 0, false, false
-}
 
-type bitset32 uint32
+	// Any remaining row with an all-zero left side and a nonzero
+	// right side means the system has no solution at all.
+	for r := pivotRow; r < x; r++ {
+		if rows[r][n].Sign() != 0 {
+			return nil, false, false
+		}
+	}
 
-func (b bitset32) at(idx int) int {
-	if b&(1<<idx) != 0 {
-		return 1
+	rank := pivotRow
+	if rank < n {
+		return nil, false, true
+	}
+	target = make([]*big.Rat, n)
+	for i, col := range pivotCols {
+		target[col] = rows[i][n]
 	}
-	return -1
+	return target, true, false
 }
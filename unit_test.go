@@ -1,6 +1,7 @@
 package unit_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/dsnet/try"
@@ -90,19 +91,318 @@ func TestMerge(t *testing.T) {
 	try.E(unit.AddType[km](length, []string{"km"}, nil))
 
 	spacetime := try.E1(unit.Merge(time, length))
+
+	// Conversions registered before the merge still work afterward.
+	if v := try.E1(unit.Convert[km](spacetime, m(5000))); v != 5 {
+		t.Fatalf("5000m = %vkm, want 5", v)
+	}
+	if v := try.E1(unit.Convert[ms](spacetime, s(2))); v != 2000 {
+		t.Fatalf("2s = %vms, want 2000", v)
+	}
+
 	type metersPerSecond float64
-	type millisecondsPerKilometer float64
 	try.E(unit.AddType[metersPerSecond](spacetime, []string{"m"}, []string{"s"}))
-	try.E(unit.AddType[millisecondsPerKilometer](spacetime, nil, []string{"km", "ms"}))
 	var fast metersPerSecond = 2 // meters per second
-	recip := try.E1(unit.Combine[millisecondsPerKilometer](spacetime, fast))
-	if recip != 0.5 {
-		t.Fatalf("want 0.5, got %v", recip)
-	}
-
 	var secs s = 3
 	dist := try.E1(unit.Combine[m](spacetime, fast, secs))
 	if dist != 6 {
 		t.Fatalf("want 6, got %v", dist)
 	}
+
+	// Merging systems that share a unit or type name is an error.
+	if _, err := unit.Merge(time, time); err == nil {
+		t.Fatal("want error merging a system with itself, got nil")
+	}
+}
+
+func TestParseDim(t *testing.T) {
+	try.F(t.Fatal)
+	s := unit.NewSystem("parsedim")
+	try.E(unit.AddBasic(s, "m"))
+	try.E(unit.AddBasic(s, "s"))
+
+	for _, tc := range []struct {
+		expr     string
+		num, den []string
+	}{
+		{"m/s", []string{"m"}, []string{"s"}},
+		{"m*m/s", []string{"m", "m"}, []string{"s"}},
+		{"m/s/s", []string{"m"}, []string{"s", "s"}},
+		{"s^-1", nil, []string{"s"}},
+		{"s²", []string{"s", "s"}, nil},
+		{"m per s squared", []string{"m"}, []string{"s", "s"}},
+	} {
+		num, den := try.E2(unit.ParseDim(s, tc.expr))
+		if !reflect.DeepEqual(num, tc.num) || !reflect.DeepEqual(den, tc.den) {
+			t.Fatalf("ParseDim(%q) = %v, %v, want %v, %v", tc.expr, num, den, tc.num, tc.den)
+		}
+	}
+
+	if _, _, err := unit.ParseDim(s, "m/m"); err == nil {
+		t.Fatal("want error parsing simplifiable expression m/m, got nil")
+	}
+
+	type metersPerSecondSquared float64
+	try.E(unit.AddTypeExpr[metersPerSecondSquared](s, "m/s^2"))
+	accel := try.E1(unit.Convert[metersPerSecondSquared](s, metersPerSecondSquared(10)))
+	if accel != 10 {
+		t.Fatalf("want 10, got %v", accel)
+	}
+}
+
+func TestAlias(t *testing.T) {
+	try.F(t.Fatal)
+	s := unit.NewSystem("alias")
+	try.E(unit.AddBasic(s, "kilobyte"))
+	try.E(unit.AddAlias(s, "KB", "kilobyte"))
+	try.E(unit.AddAlias(s, "Kbyte", "KB")) // chains through an existing alias
+
+	for _, tc := range []struct {
+		name string
+		want string
+	}{
+		{"kilobyte", "kilobyte"},
+		{"KB", "kilobyte"},
+		{"Kbyte", "kilobyte"},
+	} {
+		got, ok := unit.Canonical(s, tc.name)
+		if !ok || got != tc.want {
+			t.Fatalf("Canonical(%q) = %q, %v, want %q, true", tc.name, got, ok, tc.want)
+		}
+	}
+
+	if _, ok := unit.Canonical(s, "nope"); ok {
+		t.Fatal("Canonical(nope) reported ok for an unknown name")
+	}
+
+	type kilobyte float64
+	try.E(unit.AddType[kilobyte](s, []string{"KB"}, nil))
+	kb := try.E1(unit.Convert[kilobyte](s, kilobyte(5)))
+	if kb != 5 {
+		t.Fatalf("want 5, got %v", kb)
+	}
+
+	if err := unit.AddAlias(s, "KB", "kilobyte"); err == nil {
+		t.Fatal("want error re-adding an existing alias, got nil")
+	}
+	if err := unit.AddAlias(s, "nope2", "not-a-unit"); err == nil {
+		t.Fatal("want error aliasing to an unknown unit, got nil")
+	}
+
+	// AddBasic/AddConversion/AddAffineConversion must not silently
+	// shadow an existing alias with a disconnected root entry.
+	if err := unit.AddBasic(s, "KB"); err == nil {
+		t.Fatal("want error adding a basic unit over an existing alias, got nil")
+	}
+	if err := unit.AddConversion(s, "kilobyte", "KB", 1); err == nil {
+		t.Fatal("want error adding a conversion over an existing alias, got nil")
+	}
+	if err := unit.AddAffineConversion(s, "kilobyte", "KB", 1, 0); err == nil {
+		t.Fatal("want error adding an affine conversion over an existing alias, got nil")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	try.F(t.Fatal)
+	s := unit.NewSystem("format")
+	try.E(unit.AddBasic(s, "m"))
+	try.E(unit.AddBasic(s, "s"))
+	try.E(unit.AddConversion(s, "m", "km", 1000))
+	try.E(unit.AddConversion(s, "m", "mm", 1.0/1000))
+	try.E(unit.AddAlias(s, "kilometer", "km"))
+	type meter float64
+	type metersPerSecond float64
+	try.E(unit.AddType[meter](s, []string{"m"}, nil))
+	try.E(unit.AddType[metersPerSecond](s, []string{"m"}, []string{"s"}))
+
+	if v, name, err := unit.Format(s, meter(5000)); err != nil || v != 5 || name != "km" {
+		t.Fatalf("Format(5000m) = %v, %q, %v, want 5, km, nil", v, name, err)
+	}
+	if v, name, err := unit.Format(s, meter(0.005)); err != nil || v != 5 || name != "mm" {
+		t.Fatalf("Format(0.005m) = %v, %q, %v, want 5, mm, nil", v, name, err)
+	}
+
+	// Aliases must tie-break toward the shorter, more familiar
+	// spelling ("km" over the equally-scaled alias "kilometer").
+	if v, name, err := unit.Format(s, meter(2000)); err != nil || v != 2 || name != "km" {
+		t.Fatalf("Format(2000m) = %v, %q, %v, want 2, km, nil", v, name, err)
+	}
+
+	str := try.E1(unit.FormatString(s, meter(5000)))
+	if str != "5 km" {
+		t.Fatalf("FormatString(5000m) = %q, want %q", str, "5 km")
+	}
+
+	if _, _, err := unit.Format(s, metersPerSecond(10)); err != nil {
+		t.Fatalf("Format(10 m/s) returned unexpected error: %v", err)
+	}
+}
+
+func TestRescale(t *testing.T) {
+	try.F(t.Fatal)
+	s := unit.NewSystem("rescale")
+	try.E(unit.AddBasic(s, "m"))
+	try.E(unit.AddConversion(s, "m", "km", 1000))
+	try.E(unit.AddConversion(s, "m", "gm", 1000*1000*1000))
+	type meter float64
+	type kilometer float64
+	type gigameter float64
+	try.E(unit.AddType[meter](s, []string{"m"}, nil))
+	try.E(unit.AddType[kilometer](s, []string{"km"}, nil))
+	try.E(unit.AddType[gigameter](s, []string{"gm"}, nil))
+
+	values, name, err := unit.Rescale(s, []any{meter(5000), kilometer(2), gigameter(0.001)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "m" {
+		t.Fatalf("want rescaled into m, got %v", name)
+	}
+	want := []float64{5000, 2000, 1e6}
+	if !reflect.DeepEqual(values, want) {
+		t.Fatalf("Rescale values = %v, want %v", values, want)
+	}
+
+	total, name, err := unit.CommonUnit[meter](s, meter(5000), kilometer(2), gigameter(0.001))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "m" || total != 1007000 {
+		t.Fatalf("CommonUnit = %v %v, want 1007000 m", total, name)
+	}
+
+	type seconds float64
+	try.E(unit.AddBasic(s, "s"))
+	try.E(unit.AddType[seconds](s, []string{"s"}, nil))
+	if _, _, err := unit.Rescale(s, []any{meter(1), seconds(1)}); err == nil {
+		t.Fatal("want error rescaling incompatible dimensions, got nil")
+	}
+
+	// Ties between equally-scaled candidate units must resolve the
+	// same way every time, not flap with map iteration order.
+	try.E(unit.AddConversion(s, "m", "klicks", 1000))
+	try.E(unit.AddAlias(s, "kilometers", "klicks"))
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		_, name, err := unit.Rescale(s, []any{kilometer(5)})
+		try.E(err)
+		seen[name] = true
+	}
+	if len(seen) != 1 {
+		t.Fatalf("Rescale tie-break was unstable across runs: saw %v", seen)
+	}
+}
+
+func TestCombineCases(t *testing.T) {
+	try.F(t.Fatal)
+	s := unit.NewSystem("combine")
+	try.E(unit.AddBasic(s, "m"))
+	try.E(unit.AddBasic(s, "s"))
+	type meter float64
+	type seconds float64
+	type area float64
+	try.E(unit.AddType[meter](s, []string{"m"}, nil))
+	try.E(unit.AddType[seconds](s, []string{"s"}, nil))
+	try.E(unit.AddType[area](s, []string{"m", "m"}, nil))
+
+	// Unique, repeated-root solution: squaring a single length value.
+	a := try.E1(unit.Combine[area](s, meter(4)))
+	if a != 16 {
+		t.Fatalf("want 16 m^2, got %v", a)
+	}
+
+	// Ambiguous: two meter values could combine into a meter result in
+	// infinitely many ways (arg0^1*arg1^0, arg0^0*arg1^1, ...).
+	if _, err := unit.Combine[meter](s, meter(3), meter(4)); err == nil {
+		t.Fatal("want ambiguous-conversion error combining two meters into one meter, got nil")
+	}
+
+	// Impossible: a seconds-only value can never combine into a meter.
+	if _, err := unit.Combine[meter](s, seconds(5)); err == nil {
+		t.Fatal("want impossible-conversion error combining seconds into meter, got nil")
+	}
+}
+
+func TestAffineConversion(t *testing.T) {
+	try.F(t.Fatal)
+	s := unit.NewSystem("affine")
+	try.E(unit.AddBasic(s, "kelvin"))
+	try.E(unit.AddAffineConversion(s, "kelvin", "celsius", 1, -273.15))
+	try.E(unit.AddAffineConversion(s, "celsius", "fahrenheit", 9.0/5, 32))
+	type kelvin float64
+	type celsius float64
+	type fahrenheit float64
+	try.E(unit.AddType[kelvin](s, []string{"kelvin"}, nil))
+	try.E(unit.AddType[celsius](s, []string{"celsius"}, nil))
+	try.E(unit.AddType[fahrenheit](s, []string{"fahrenheit"}, nil))
+
+	if f := try.E1(unit.Convert[fahrenheit](s, celsius(0))); f != 32 {
+		t.Fatalf("0 celsius = %v fahrenheit, want 32", f)
+	}
+	// Chained through celsius, so this exercises both AddAffineConversion hops at once.
+	if f := try.E1(unit.Convert[fahrenheit](s, kelvin(273.15))); f != 32 {
+		t.Fatalf("273.15 kelvin = %v fahrenheit, want 32", f)
+	}
+	if k := try.E1(unit.Convert[kelvin](s, fahrenheit(32))); k != 273.15 {
+		t.Fatalf("32 fahrenheit = %v kelvin, want 273.15", k)
+	}
+
+	// Affine units aren't closed under multiplication: neither AddType
+	// nor Combine should allow one into a derived type.
+	type celsiusPerSecond float64
+	try.E(unit.AddBasic(s, "s"))
+	if err := unit.AddType[celsiusPerSecond](s, []string{"celsius"}, []string{"s"}); err == nil {
+		t.Fatal("want error building a derived type out of an affine unit, got nil")
+	}
+	if _, err := unit.Combine[celsius](s, kelvin(300)); err == nil {
+		t.Fatal("want error combining into an affine type, got nil")
+	}
+	if _, err := unit.Combine[kelvin](s, celsius(10)); err == nil {
+		t.Fatal("want error combining with an affine-typed argument, got nil")
+	}
+}
+
+func TestSIPrefixes(t *testing.T) {
+	try.F(t.Fatal)
+	s := unit.NewSystem("siprefixes")
+	try.E(unit.AddBasic(s, "m"))
+	try.E(unit.AddSIPrefixes(s, "m", unit.SIMetric...))
+	try.E(unit.AddBasic(s, "byte"))
+	try.E(unit.AddSIPrefixes(s, "byte", unit.SIBinary...))
+	type meter float64
+	type kilometer float64
+	type millimeter float64
+	type microAscii float64
+	type kibibyte float64
+	try.E(unit.AddType[meter](s, []string{"m"}, nil))
+	try.E(unit.AddType[kilometer](s, []string{"km"}, nil))
+	try.E(unit.AddType[millimeter](s, []string{"mm"}, nil))
+	try.E(unit.AddType[microAscii](s, []string{"um"}, nil))
+	try.E(unit.AddType[kibibyte](s, []string{"Kibyte"}, nil))
+
+	if v := try.E1(unit.Convert[meter](s, kilometer(2))); v != 2000 {
+		t.Fatalf("2 km = %v m, want 2000", v)
+	}
+	if v := try.E1(unit.Convert[meter](s, millimeter(1))); v != 0.001 {
+		t.Fatalf("1 mm = %v m, want 0.001", v)
+	}
+	if v := try.E1(unit.Convert[meter](s, microAscii(1))); v != 1e-6 {
+		t.Fatalf("1 um = %v m, want 1e-6", v)
+	}
+	if v, name, err := unit.Format(s, meter(5000)); err != nil || v != 5 || name != "km" {
+		t.Fatalf("Format(5000m) = %v, %q, %v, want 5, km, nil", v, name, err)
+	}
+	if v := try.E1(unit.Convert[kibibyte](s, kibibyte(1))); v != 1 {
+		t.Fatalf("1 Kibyte = %v Kibyte, want 1", v)
+	}
+
+	for _, name := range []string{"km", "mm", "µm", "um", "kilom", "millim", "Kibyte"} {
+		if _, ok := unit.Canonical(s, name); !ok {
+			t.Fatalf("Canonical(%q) not found after AddSIPrefixes", name)
+		}
+	}
+
+	if err := unit.AddSIPrefixes(s, "m", unit.SIMetric...); err == nil {
+		t.Fatal("want error re-registering SI prefixes over an existing unit, got nil")
+	}
 }